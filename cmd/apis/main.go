@@ -1,79 +1,121 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"flag"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"sort"
-
-	"crypto/sha256"
-	"encoding/hex"
+	"strings"
+	"time"
 
 	"github.com/maisam9060/platform-api/internal/cache"
 	"github.com/maisam9060/platform-api/internal/config"
+	"github.com/maisam9060/platform-api/internal/hash"
 	"gopkg.in/yaml.v3"
 )
 
-// HashDir recursively hashes all files in a directory
-func HashDir(dir string) (string, error) {
-	var files []string
+// saveDockerImage captures a built image as a tarball via `docker save`,
+// the same form `docker load` expects on restore.
+func saveDockerImage(tag string) ([]byte, error) {
+	cmd := exec.Command("docker", "save", tag)
+	return cmd.Output()
+}
+
+// loadDockerImage restores an image tarball produced by saveDockerImage
+// into the local docker daemon.
+func loadDockerImage(data []byte) error {
+	cmd := exec.Command("docker", "load")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// outputDigests hashes each declared output so a restored artifact can
+// later be checked against the metadata it was cached with. HashDir
+// hashes a single file directly when out isn't a directory.
+func outputDigests(hasher hash.Hasher, outputs []string) (map[string]string, error) {
+	digests := make(map[string]string, len(outputs))
+	for _, out := range outputs {
+		d, err := hash.HashDir(hasher, out, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if !info.IsDir() {
-			files = append(files, path)
-		}
-		return nil
-	})
-	if err != nil {
-		return "", err
+		digests[out] = d
 	}
+	return digests, nil
+}
 
-	sort.Strings(files)
+// captureArtifact builds the cache entry for a freshly built feature:
+// the docker image tarball, an archive of its declared outputs (if
+// any), and metadata recording how it was produced.
+func captureArtifact(feat *config.Feature, dockerTag, contentHash string, depHashes map[string]string, hasher hash.Hasher) (cache.Entry, error) {
+	image, err := saveDockerImage(dockerTag)
+	if err != nil {
+		return cache.Entry{}, fmt.Errorf("docker save %s: %w", dockerTag, err)
+	}
 
-	h := sha256.New()
-	for _, f := range files {
-		file, err := os.Open(f)
+	var outputs []byte
+	digests := map[string]string{}
+	if len(feat.Outputs) > 0 {
+		outputs, err = cache.ArchiveOutputs(feat.Outputs)
 		if err != nil {
-			return "", err
+			return cache.Entry{}, fmt.Errorf("archiving outputs for %s: %w", feat.Name, err)
 		}
-		defer file.Close()
-		if _, err := io.Copy(h, file); err != nil {
-			return "", err
+		digests, err = outputDigests(hasher, feat.Outputs)
+		if err != nil {
+			return cache.Entry{}, err
 		}
 	}
 
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return cache.Entry{
+		Hash:    contentHash,
+		Data:    image,
+		Outputs: outputs,
+		Meta: cache.Metadata{
+			Feature:          feat.Name,
+			Command:          feat.Command,
+			Timestamp:        time.Now().Unix(),
+			DependencyHashes: depHashes,
+			OutputDigests:    digests,
+		},
+	}, nil
 }
 
-// ComputeFeatureHash computes a hash of feature inputs and dependencies
-func ComputeFeatureHash(feat *config.Feature, depHashes []string) (string, error) {
-	h := sha256.New()
-
-	// Hash feature command
-	h.Write([]byte(feat.Command))
-
-	// Hash all input directories
-	for _, input := range feat.Inputs {
-		hash, err := HashDir(input)
-		if err != nil {
-			return "", err
+// restoreArtifact loads a cached entry back into the working state:
+// `docker load`s the image tarball, extracts any archived outputs, and
+// re-hashes those outputs to confirm they match entry.Meta.OutputDigests,
+// the digests they were captured with.
+func restoreArtifact(entry cache.Entry, hasher hash.Hasher) error {
+	if len(entry.Data) > 0 {
+		if err := loadDockerImage(entry.Data); err != nil {
+			return fmt.Errorf("docker load: %w", err)
 		}
-		h.Write([]byte(hash))
 	}
-
-	// Hash dependency hashes (sorted)
-	sort.Strings(depHashes)
-	for _, dh := range depHashes {
-		h.Write([]byte(dh))
+	if len(entry.Outputs) > 0 {
+		if err := cache.ExtractOutputs(entry.Outputs); err != nil {
+			return fmt.Errorf("extracting outputs: %w", err)
+		}
 	}
-
-	return hex.EncodeToString(h.Sum(nil)), nil
+	if len(entry.Meta.OutputDigests) > 0 {
+		outputs := make([]string, 0, len(entry.Meta.OutputDigests))
+		for out := range entry.Meta.OutputDigests {
+			outputs = append(outputs, out)
+		}
+		digests, err := outputDigests(hasher, outputs)
+		if err != nil {
+			return fmt.Errorf("re-hashing restored outputs: %w", err)
+		}
+		for out, want := range entry.Meta.OutputDigests {
+			if digests[out] != want {
+				return fmt.Errorf("restored output %s does not match cached digest", out)
+			}
+		}
+	}
+	return nil
 }
 
 func buildGraph(cfg *config.BuilderConfig) map[string][]string {
@@ -86,48 +128,30 @@ func buildGraph(cfg *config.BuilderConfig) map[string][]string {
 	return graph
 }
 
-func topoSort(
-	node string,
-	graph map[string][]string,
-	visited map[string]bool,
-	temp map[string]bool,
-	order *[]string,
-) error {
-
-	if temp[node] {
-		return fmt.Errorf("cycle detected at feature: %s", node)
-	}
-
-	if visited[node] {
-		return nil
-	}
-
-	temp[node] = true
-
-	for _, dep := range graph[node] {
-		if _, ok := graph[dep]; !ok {
-			return fmt.Errorf("unknown dependency: %s", dep)
-		}
-		if err := topoSort(dep, graph, visited, temp, order); err != nil {
-			return err
-		}
-	}
-
-	temp[node] = false
-	visited[node] = true
-	*order = append(*order, node)
-
-	return nil
-}
-
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: builder <command> <feature>")
+	jobs := flag.Int("jobs", 4, "number of features to build concurrently")
+	dryRun := flag.Bool("dry-run", false, "print the planned build schedule without building anything")
+	target := flag.String("target", "", "comma-separated feature names; trims the graph to only their ancestors")
+	hashAlgo := flag.String("hash", "", "hash algorithm to use (sha256, sha1, blake3); overrides hash_algorithm in builder.yaml")
+	backendName := flag.String("backend", "", "build backend to use (docker, buildkit, buildah); overrides backend in builder.yaml")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		fmt.Println("Usage: builder [flags] <command> [feature]")
 		os.Exit(1)
 	}
 
-	// command := os.Args[1]
-	featureName := os.Args[2]
+	// command := args[0]
+	var roots []string
+	if *target != "" {
+		roots = strings.Split(*target, ",")
+	} else if len(args) >= 2 {
+		roots = []string{args[1]}
+	} else {
+		fmt.Println("Usage: builder [flags] <command> <feature> (or pass --target)")
+		os.Exit(1)
+	}
 
 	// Load YAML
 	data, err := ioutil.ReadFile("builder.yaml")
@@ -148,69 +172,45 @@ func main() {
 		feat.Name = name
 	}
 
-	// // Validate feature
-	// feat, ok := cfg.Features[featureName]
-	// if !ok {
-	// 	fmt.Println("Feature not found:", featureName)
-	// 	os.Exit(1)
-	// }
-
-	// --- Step 4: Topo sort and build features in order ---
-	graph := buildGraph(&cfg)
-
-	visited := make(map[string]bool)
-	temp := make(map[string]bool)
-	var buildOrder []string
-
-	err = topoSort(featureName, graph, visited, temp, &buildOrder)
+	graph, err := ancestorsOf(buildGraph(&cfg), roots)
 	if err != nil {
 		fmt.Println("Dependency error:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Build order:", buildOrder)
-
-	// Build features in order
-	hashCache := make(map[string]string) // cache of this run
-	for _, fname := range buildOrder {
-		feat := cfg.Features[fname]
-
-		// Collect dependency hashes
-		var depHashes []string
-		for _, dep := range feat.DependsOn {
-			depHashes = append(depHashes, hashCache[dep])
-		}
-
-		// Compute current feature hash
-		newHash, err := ComputeFeatureHash(feat, depHashes)
+	algo := *hashAlgo
+	if algo == "" {
+		algo = cfg.HashAlgorithm
+	}
+	var hasher hash.Hasher
+	if algo == "" {
+		hasher = hash.Default()
+	} else {
+		hasher, err = hash.Lookup(algo)
 		if err != nil {
-			fmt.Printf("Error hashing feature %s: %v\n", fname, err)
+			fmt.Println(err)
 			os.Exit(1)
 		}
+	}
 
-		oldHash, err := cache.ReadFeatureHash(fname)
-		if err == nil && oldHash == newHash {
-			fmt.Println("SKIP", fname)
-			hashCache[fname] = newHash
-			continue
-		}
-
-		fmt.Println("BUILD", fname)
+	backend := *backendName
+	if backend == "" {
+		backend = cfg.Backend
+	}
 
-		// --- Docker build ---
-		dockerTag := fmt.Sprintf("%s:%s", fname, newHash[:8]) // short hash
-		cmd := exec.Command("docker", "build", "-t", dockerTag, feat.Inputs[0])
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	store := cache.NewDefaultStore()
+	sched := NewScheduler(&cfg, graph, store, hasher, backend, *jobs)
 
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("Docker build failed for %s: %v\n", fname, err)
+	if *dryRun {
+		if err := sched.PrintPlan(); err != nil {
+			fmt.Println("Dependency error:", err)
 			os.Exit(1)
 		}
-
-		// Write hash cache
-		cache.WriteFeatureHash(fname, newHash)
-		hashCache[fname] = newHash
+		return
 	}
 
+	if err := sched.Run(context.Background()); err != nil {
+		fmt.Println("Build failed:", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/maisam9060/platform-api/internal/builder"
+	"github.com/maisam9060/platform-api/internal/cache"
+	"github.com/maisam9060/platform-api/internal/config"
+	"github.com/maisam9060/platform-api/internal/feature"
+	"github.com/maisam9060/platform-api/internal/hash"
+)
+
+// ancestorsOf returns the sub-graph of full reachable via dependency
+// edges from roots (including the roots themselves). It backs
+// --target: building a subset of features should only walk the
+// features they actually depend on.
+func ancestorsOf(full map[string][]string, roots []string) (map[string][]string, error) {
+	keep := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if keep[name] {
+			return nil
+		}
+		deps, ok := full[name]
+		if !ok {
+			return fmt.Errorf("unknown feature: %s", name)
+		}
+		keep[name] = true
+		for _, dep := range deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+
+	trimmed := make(map[string][]string, len(keep))
+	for name := range keep {
+		trimmed[name] = full[name]
+	}
+	return trimmed, nil
+}
+
+// Scheduler builds the features of a dependency graph concurrently,
+// running up to Jobs builds at once and starting each feature as soon
+// as every feature it depends on has finished.
+type Scheduler struct {
+	cfg     *config.BuilderConfig
+	graph   map[string][]string // feature -> its dependencies
+	store   cache.Store
+	hasher  hash.Hasher
+	backend string
+	jobs    int
+}
+
+// NewScheduler returns a Scheduler for graph, bounding concurrent
+// builds to jobs (at least 1) and building with the named backend (see
+// builder.Lookup).
+func NewScheduler(cfg *config.BuilderConfig, graph map[string][]string, store cache.Store, hasher hash.Hasher, backend string, jobs int) *Scheduler {
+	if jobs < 1 {
+		jobs = 1
+	}
+	return &Scheduler{cfg: cfg, graph: graph, store: store, hasher: hasher, backend: backend, jobs: jobs}
+}
+
+// degrees computes, for every node in the graph, the number of
+// dependencies it's still waiting on (in-degree) and the reverse
+// edges (dependents) used to decrement that count as builds finish.
+func (s *Scheduler) degrees() (inDegree map[string]int, dependents map[string][]string) {
+	inDegree = make(map[string]int, len(s.graph))
+	dependents = make(map[string][]string, len(s.graph))
+	for name, deps := range s.graph {
+		inDegree[name] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+	return inDegree, dependents
+}
+
+// Levels groups the graph's nodes into build levels: level 0 has no
+// remaining dependencies once earlier levels are done, and every
+// feature in a level can build concurrently with the rest of it.
+func (s *Scheduler) Levels() ([][]string, error) {
+	inDegree, dependents := s.degrees()
+
+	var levels [][]string
+	remaining := len(inDegree)
+
+	var current []string
+	for name, deg := range inDegree {
+		if deg == 0 {
+			current = append(current, name)
+		}
+	}
+
+	for len(current) > 0 {
+		sort.Strings(current)
+		levels = append(levels, current)
+		remaining -= len(current)
+
+		var next []string
+		for _, name := range current {
+			for _, dependent := range dependents[name] {
+				inDegree[dependent]--
+				if inDegree[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("cycle detected among scheduled features")
+	}
+	return levels, nil
+}
+
+// PrintPlan prints the graph's build levels (see Levels) without
+// building anything, for --dry-run.
+func (s *Scheduler) PrintPlan() error {
+	levels, err := s.Levels()
+	if err != nil {
+		return err
+	}
+	for i, level := range levels {
+		fmt.Printf("Level %d: %s\n", i, strings.Join(level, ", "))
+	}
+	return nil
+}
+
+// Run builds every feature in the graph, starting each one as soon as
+// its dependencies have completed, with at most s.jobs builds running
+// at once. The first build error cancels ctx so in-flight builds wind
+// down and no new ones start; that error is returned once every
+// goroutine has exited.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if _, err := s.Levels(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	inDegree, _ := s.degrees()
+
+	done := make(map[string]chan struct{}, len(inDegree))
+	for name := range inDegree {
+		done[name] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, s.jobs)
+
+	var (
+		mu       sync.Mutex
+		outMu    sync.Mutex
+		hashes   = make(map[string]string)
+		firstErr error
+	)
+
+	var wg sync.WaitGroup
+	for name := range inDegree {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer close(done[name])
+
+			feat := s.cfg.Features[name]
+			for _, dep := range feat.DependsOn {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			mu.Lock()
+			depHashes := make(map[string]string, len(feat.DependsOn))
+			for _, dep := range feat.DependsOn {
+				depHashes[dep] = hashes[dep]
+			}
+			mu.Unlock()
+
+			out := newPrefixWriter(name, os.Stdout, &outMu)
+			newHash, err := buildFeature(ctx, feat, depHashes, s.store, s.hasher, s.backend, out)
+			out.Flush()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", name, err)
+					cancel()
+				}
+				return
+			}
+			hashes[name] = newHash
+		}(name)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// buildFeature computes feat's content hash, serves a cache hit if its
+// artifact restores cleanly, and otherwise builds it and populates the
+// cache. It returns feat's content hash so dependents can fold it into
+// their own hash.
+func buildFeature(ctx context.Context, feat *config.Feature, depHashes map[string]string, store cache.Store, hasher hash.Hasher, backendName string, out io.Writer) (string, error) {
+	newHash, err := feature.ComputeFeatureHash(hasher, feat, depHashes)
+	if err != nil {
+		return "", fmt.Errorf("hashing: %w", err)
+	}
+
+	key := cache.Key{Feature: feat.Name, Hash: newHash}
+	if entry, err := store.Get(key); err == nil {
+		if restoreErr := restoreArtifact(entry, hasher); restoreErr == nil {
+			fmt.Fprintln(out, "SKIP", feat.Name)
+			return newHash, nil
+		} else {
+			fmt.Fprintf(out, "Cache hit for %s but restore failed, rebuilding: %v\n", feat.Name, restoreErr)
+		}
+	}
+
+	fmt.Fprintln(out, "BUILD", feat.Name)
+
+	dockerTag := fmt.Sprintf("%s:%s", feat.Name, shortHash(newHash))
+
+	backend, err := builder.Lookup(backendName, out, out)
+	if err != nil {
+		return "", err
+	}
+	contextDir := feat.Context
+	if contextDir == "" && len(feat.Inputs) > 0 {
+		contextDir = feat.Inputs[0]
+	}
+	if contextDir == "" {
+		return "", fmt.Errorf("feature %s: no context or inputs", feat.Name)
+	}
+	_, err = backend.Build(ctx, builder.BuildRequest{
+		ContextDir: contextDir,
+		Dockerfile: feat.Dockerfile,
+		Tags:       []string{dockerTag},
+		BuildArgs:  feat.BuildArgs,
+		Secrets:    feat.Secrets,
+		SSH:        feat.SSH,
+		CacheFrom:  feat.CacheFrom,
+		CacheTo:    feat.CacheTo,
+		Platforms:  feat.Platforms,
+		Target:     feat.Target,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := captureArtifact(feat, dockerTag, newHash, depHashes, hasher)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: failed to capture artifact for %s: %v\n", feat.Name, err)
+		return newHash, nil
+	}
+	if err := store.Put(key, entry); err != nil {
+		fmt.Fprintf(out, "Warning: failed to write cache entry for %s: %v\n", feat.Name, err)
+	}
+	return newHash, nil
+}
+
+// shortHash returns a short, docker-tag-safe form of a feature content
+// hash, stripping the "algo:" prefix ComputeFeatureHash adds.
+func shortHash(h string) string {
+	if i := strings.IndexByte(h, ':'); i >= 0 {
+		h = h[i+1:]
+	}
+	if len(h) > 8 {
+		h = h[:8]
+	}
+	return h
+}
+
+// prefixWriter prefixes every line written to it with a feature name
+// before forwarding to out, guarded by a shared mutex so concurrent
+// builds don't interleave mid-line.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	rem    []byte
+}
+
+func newPrefixWriter(feature string, out io.Writer, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{prefix: "[" + feature + "] ", out: out, mu: mu}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.rem = append(w.rem, p...)
+	for {
+		i := bytes.IndexByte(w.rem, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.rem[:i+1])
+		w.rem = w.rem[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes out any trailing partial line left without a newline.
+func (w *prefixWriter) Flush() {
+	if len(w.rem) == 0 {
+		return
+	}
+	w.writeLine(append(w.rem, '\n'))
+	w.rem = nil
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	io.WriteString(w.out, w.prefix)
+	w.out.Write(line)
+}
@@ -0,0 +1,43 @@
+// Package builder abstracts over the tool that actually turns a
+// Dockerfile into an image, so the scheduler doesn't have to care
+// whether that's the docker daemon, BuildKit, or buildah.
+package builder
+
+import "context"
+
+// BuildRequest describes a single image build, independent of which
+// Backend executes it.
+type BuildRequest struct {
+	// ContextDir is the build context sent to the backend.
+	ContextDir string
+	// Dockerfile is the path to the dockerfile, relative to
+	// ContextDir. Empty means the backend's default ("Dockerfile").
+	Dockerfile string
+	// Tags are the image references to apply to the result.
+	Tags []string
+	// BuildArgs are passed through as --build-arg KEY=VALUE.
+	BuildArgs map[string]string
+	// Secrets are passed through as --secret (e.g. "id=foo,src=path").
+	Secrets []string
+	// SSH forwards ssh agent sockets (e.g. "default" or "id=path").
+	SSH []string
+	// Platforms requests one or more target platforms
+	// (e.g. "linux/amd64,linux/arm64").
+	Platforms []string
+	// Target selects a build stage in a multi-stage dockerfile.
+	Target string
+	// CacheFrom and CacheTo reference external build caches (e.g. the
+	// module's own content-addressable store, fronted by a registry).
+	CacheFrom []string
+	CacheTo   []string
+}
+
+// BuildResult is what a successful build produced.
+type BuildResult struct {
+	Tags []string
+}
+
+// Backend builds a docker-compatible image from a BuildRequest.
+type Backend interface {
+	Build(ctx context.Context, req BuildRequest) (BuildResult, error)
+}
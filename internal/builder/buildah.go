@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BuildahBackend builds images with buildah, rootless and without a
+// daemon. It accepts the same build flags as docker/buildkit via
+// `buildah bud`.
+type BuildahBackend struct {
+	Stdout, Stderr io.Writer
+}
+
+func (b *BuildahBackend) Build(ctx context.Context, req BuildRequest) (BuildResult, error) {
+	args := append([]string{"bud"}, dockerStyleArgs(req)...)
+	args = append(args, req.ContextDir)
+
+	cmd := exec.CommandContext(ctx, "buildah", args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	if err := cmd.Run(); err != nil {
+		return BuildResult{}, fmt.Errorf("buildah bud: %w", err)
+	}
+	return BuildResult{Tags: req.Tags}, nil
+}
+
+// dockerStyleArgs renders req using the flags shared by `docker build`
+// and `buildah bud`.
+func dockerStyleArgs(req BuildRequest) []string {
+	var args []string
+
+	if req.Dockerfile != "" {
+		args = append(args, "-f", filepath.Join(req.ContextDir, req.Dockerfile))
+	}
+	for _, tag := range req.Tags {
+		args = append(args, "-t", tag)
+	}
+	for k, v := range req.BuildArgs {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, v))
+	}
+	for _, s := range req.Secrets {
+		args = append(args, "--secret", s)
+	}
+	for _, s := range req.SSH {
+		args = append(args, "--ssh", s)
+	}
+	if len(req.Platforms) > 0 {
+		args = append(args, "--platform", strings.Join(req.Platforms, ","))
+	}
+	if req.Target != "" {
+		args = append(args, "--target", req.Target)
+	}
+	for _, c := range req.CacheFrom {
+		args = append(args, "--cache-from", c)
+	}
+	for _, c := range req.CacheTo {
+		args = append(args, "--cache-to", c)
+	}
+	return args
+}
+
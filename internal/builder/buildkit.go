@@ -0,0 +1,68 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BuildKitBackend builds images via buildctl, BuildKit's CLI, using
+// the dockerfile frontend. Each cache-from/cache-to entry is passed to
+// buildctl as a registry cache reference (type=registry,ref=<entry>),
+// e.g. a registry fronting this module's own content-addressable
+// store; other BuildKit cache types are not used.
+type BuildKitBackend struct {
+	Stdout, Stderr io.Writer
+}
+
+func (b *BuildKitBackend) Build(ctx context.Context, req BuildRequest) (BuildResult, error) {
+	dockerfile := req.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	dockerfileDir := filepath.Dir(filepath.Join(req.ContextDir, dockerfile))
+
+	args := []string{
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + req.ContextDir,
+		"--local", "dockerfile=" + dockerfileDir,
+		"--opt", "filename=" + filepath.Base(dockerfile),
+	}
+
+	if req.Target != "" {
+		args = append(args, "--opt", "target="+req.Target)
+	}
+	for k, v := range req.BuildArgs {
+		args = append(args, "--opt", fmt.Sprintf("build-arg:%s=%s", k, v))
+	}
+	if len(req.Platforms) > 0 {
+		args = append(args, "--opt", "platform="+strings.Join(req.Platforms, ","))
+	}
+	for _, s := range req.Secrets {
+		args = append(args, "--secret", s)
+	}
+	for _, s := range req.SSH {
+		args = append(args, "--ssh", s)
+	}
+	for _, c := range req.CacheFrom {
+		args = append(args, "--import-cache", "type=registry,ref="+c)
+	}
+	for _, c := range req.CacheTo {
+		args = append(args, "--export-cache", "type=registry,ref="+c)
+	}
+	for _, tag := range req.Tags {
+		args = append(args, "--output", "type=docker,name="+tag)
+	}
+
+	cmd := exec.CommandContext(ctx, "buildctl", args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	if err := cmd.Run(); err != nil {
+		return BuildResult{}, fmt.Errorf("buildctl build: %w", err)
+	}
+	return BuildResult{Tags: req.Tags}, nil
+}
@@ -0,0 +1,28 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// DockerBackend builds images with the local docker daemon via the
+// docker CLI. It's the default backend and the one the builder always
+// had before Backend existed.
+type DockerBackend struct {
+	Stdout, Stderr io.Writer
+}
+
+func (b *DockerBackend) Build(ctx context.Context, req BuildRequest) (BuildResult, error) {
+	args := append([]string{"build"}, dockerStyleArgs(req)...)
+	args = append(args, req.ContextDir)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	if err := cmd.Run(); err != nil {
+		return BuildResult{}, fmt.Errorf("docker build: %w", err)
+	}
+	return BuildResult{Tags: req.Tags}, nil
+}
@@ -0,0 +1,22 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+)
+
+// Lookup returns the Backend named by name, streaming its build
+// output to stdout/stderr. An empty name selects the docker backend,
+// matching the builder's behavior before Backend existed.
+func Lookup(name string, stdout, stderr io.Writer) (Backend, error) {
+	switch name {
+	case "", "docker":
+		return &DockerBackend{Stdout: stdout, Stderr: stderr}, nil
+	case "buildkit":
+		return &BuildKitBackend{Stdout: stdout, Stderr: stderr}, nil
+	case "buildah":
+		return &BuildahBackend{Stdout: stdout, Stderr: stderr}, nil
+	default:
+		return nil, fmt.Errorf("builder: unknown backend %q", name)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveOutputsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.MkdirAll("out/nested", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("out/nested/file.txt", []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("single.txt", []byte("world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ArchiveOutputs([]string{"out", "single.txt"})
+	if err != nil {
+		t.Fatalf("ArchiveOutputs: %v", err)
+	}
+
+	if err := os.RemoveAll("out"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove("single.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExtractOutputs(data); err != nil {
+		t.Fatalf("ExtractOutputs: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join("out", "nested", "file.txt"))
+	if err != nil {
+		t.Fatalf("reading restored nested file: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("out/nested/file.txt = %q, want %q", got, "hello")
+	}
+
+	got, err = os.ReadFile("single.txt")
+	if err != nil {
+		t.Fatalf("reading restored single file: %v", err)
+	}
+	if string(got) != "world" {
+		t.Errorf("single.txt = %q, want %q", got, "world")
+	}
+}
@@ -1,26 +1,25 @@
+// Package cache provides a content-addressable Store for build
+// artifacts, keyed by feature name and content hash, plus the
+// concrete backends (filesystem, http) and middleware (ChainStore)
+// used to compose them.
 package cache
 
-import (
-	"os"
-	"path/filepath"
-)
+import "os"
 
-func FeatureHashPath(feature string) string {
-	return filepath.Join(".builder-cache", feature, "hash")
-}
+// NewDefaultStore builds the Store used by the builder when no
+// explicit configuration overrides it: a FilesystemStore rooted at
+// BUILDER_CACHE_PATH (default .builder-cache), optionally chained in
+// front of an HTTPStore when BUILDER_CACHE_REMOTE_URL is set, so a
+// local miss falls back to the remote store and a remote hit is
+// written back locally.
+func NewDefaultStore() Store {
+	local := NewFilesystemStore("")
 
-func ReadFeatureHash(feature string) (string, error) {
-	data, err := os.ReadFile(FeatureHashPath(feature))
-	if err != nil {
-		return "", err
+	remoteURL := os.Getenv("BUILDER_CACHE_REMOTE_URL")
+	if remoteURL == "" {
+		return local
 	}
-	return string(data), nil
-}
 
-func WriteFeatureHash(feature, hash string) error {
-	dir := filepath.Dir(FeatureHashPath(feature))
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(FeatureHashPath(feature), []byte(hash), 0644)
+	remote := NewHTTPStore(remoteURL, os.Getenv("BUILDER_CACHE_REMOTE_TOKEN"))
+	return NewChainStore(local, remote)
 }
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCachePath is used when BUILDER_CACHE_PATH is unset.
+const DefaultCachePath = ".builder-cache"
+
+// FilesystemStore stores entries as files under a root directory, one
+// file per Key at <root>/<feature>/<hash>.
+type FilesystemStore struct {
+	root string
+}
+
+// NewFilesystemStore returns a FilesystemStore rooted at root. If root
+// is empty, BUILDER_CACHE_PATH is used, falling back to
+// DefaultCachePath.
+func NewFilesystemStore(root string) *FilesystemStore {
+	if root == "" {
+		root = os.Getenv("BUILDER_CACHE_PATH")
+	}
+	if root == "" {
+		root = DefaultCachePath
+	}
+	return &FilesystemStore{root: root}
+}
+
+func (s *FilesystemStore) path(key Key) string {
+	// Hash may carry an "algo:digest" prefix (see hash.Hasher); colons
+	// aren't safe in path components on every filesystem.
+	hash := strings.ReplaceAll(key.Hash, ":", "-")
+	return filepath.Join(s.root, key.Feature, hash)
+}
+
+func (s *FilesystemStore) Get(key Key) (Entry, error) {
+	raw, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	return decodeEntry(raw)
+}
+
+func (s *FilesystemStore) Put(key Key, entry Entry) error {
+	raw, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path(key))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(key), raw, 0644)
+}
+
+func (s *FilesystemStore) Has(key Key) (bool, error) {
+	if _, err := os.Stat(s.path(key)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
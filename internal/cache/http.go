@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPStore stores entries on a remote server, GETting and PUTting
+// them under /<feature>/<hash>, authenticated with a bearer token.
+type HTTPStore struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// NewHTTPStore returns an HTTPStore against baseURL, authenticating
+// requests with token.
+func NewHTTPStore(baseURL, token string) *HTTPStore {
+	return &HTTPStore{
+		BaseURL: baseURL,
+		Token:   token,
+		Client:  http.DefaultClient,
+	}
+}
+
+func (s *HTTPStore) url(key Key) string {
+	return fmt.Sprintf("%s/%s/%s", s.BaseURL, key.Feature, key.Hash)
+}
+
+func (s *HTTPStore) do(req *http.Request) (*http.Response, error) {
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	return s.Client.Do(req)
+}
+
+func (s *HTTPStore) Get(key Key) (Entry, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url(key), nil)
+	if err != nil {
+		return Entry{}, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Entry{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Entry{}, fmt.Errorf("cache: remote GET %s: %s", s.url(key), resp.Status)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Entry{}, err
+	}
+	return decodeEntry(raw)
+}
+
+func (s *HTTPStore) Put(key Key, entry Entry) error {
+	raw, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, s.url(key), bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("cache: remote PUT %s: %s", s.url(key), resp.Status)
+	}
+	return nil
+}
+
+func (s *HTTPStore) Has(key Key) (bool, error) {
+	req, err := http.NewRequest(http.MethodHead, s.url(key), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("cache: remote HEAD %s: %s", s.url(key), resp.Status)
+	}
+}
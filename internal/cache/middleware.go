@@ -0,0 +1,61 @@
+package cache
+
+// ChainStore reads through an ordered list of stores, returning the
+// first hit, and write-populates every earlier store in the chain so
+// subsequent lookups are served locally. Put writes to every store in
+// the chain in order, stopping at the first error; Has reports whether
+// any store in the chain has the key.
+//
+// A typical chain is [filesystem, http]: local read-through backed by
+// a shared remote, so a miss on the local store falls back to remote
+// and a remote hit gets cached locally for next time.
+type ChainStore struct {
+	stores []Store
+}
+
+// NewChainStore returns a ChainStore querying stores in order.
+func NewChainStore(stores ...Store) *ChainStore {
+	return &ChainStore{stores: stores}
+}
+
+func (c *ChainStore) Get(key Key) (Entry, error) {
+	for i, s := range c.stores {
+		entry, err := s.Get(key)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return Entry{}, err
+		}
+
+		// Populate earlier stores on hit (e.g. local on a remote hit).
+		// Best-effort: a failure here shouldn't fail the Get.
+		for _, back := range c.stores[:i] {
+			_ = back.Put(key, entry)
+		}
+		return entry, nil
+	}
+	return Entry{}, ErrNotFound
+}
+
+func (c *ChainStore) Put(key Key, entry Entry) error {
+	for _, s := range c.stores {
+		if err := s.Put(key, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ChainStore) Has(key Key) (bool, error) {
+	for _, s := range c.stores {
+		ok, err := s.Has(key)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
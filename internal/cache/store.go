@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+// ErrNotFound is returned by a Store when no entry exists for a key.
+var ErrNotFound = errors.New("cache: entry not found")
+
+// Key identifies a cache entry. It pairs the feature name with its
+// computed content hash so entries are content-addressable: the same
+// feature producing the same hash on two different machines resolves
+// to the same entry, which is what lets a cache be shared across
+// machines and CI runners.
+type Key struct {
+	Feature string
+	Hash    string
+}
+
+// Metadata describes the provenance of a cached artifact so a restore
+// can be validated rather than blindly trusted.
+type Metadata struct {
+	Feature   string
+	Command   string
+	Timestamp int64
+	// DependencyHashes maps each dependency feature to the content
+	// hash it was built with.
+	DependencyHashes map[string]string
+	// OutputDigests maps each declared output path to the hash of its
+	// captured contents, so a restore can be checked for corruption.
+	OutputDigests map[string]string
+}
+
+// Entry is a single cached value for a Key: the built artifact plus
+// metadata describing how it was produced.
+type Entry struct {
+	Hash string
+	// Data is the docker-save tarball of the feature's image, when the
+	// feature builds one.
+	Data []byte
+	// Outputs is a gzipped tar of the feature's declared output files
+	// and directories (config.Feature.Outputs), when any are declared.
+	Outputs []byte
+	Meta    Metadata
+}
+
+// encodeEntry serializes an entry for storage by a backend. Backends treat
+// the result as an opaque blob; only the cache package needs to know
+// its shape.
+func encodeEntry(entry Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeEntry is the inverse of encodeEntry.
+func decodeEntry(data []byte) (Entry, error) {
+	var entry Entry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Store is a backend capable of storing and retrieving cache entries.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry stored under key, or ErrNotFound if absent.
+	Get(key Key) (Entry, error)
+	// Put stores entry under key, overwriting any existing entry.
+	Put(key Key, entry Entry) error
+	// Has reports whether an entry exists for key without fetching it.
+	Has(key Key) (bool, error)
+}
@@ -5,8 +5,49 @@ type Feature struct {
 	Inputs    []string `yaml:"inputs"`
 	Command   string   `yaml:"command"`
 	DependsOn []string `yaml:"depends_on"`
+	// Outputs declares the files or directories this feature produces
+	// besides its docker image, so they can be captured into the
+	// artifact cache and restored on a cache hit.
+	Outputs []string `yaml:"outputs"`
+	// Include and Exclude are gitignore-style glob patterns (see
+	// internal/ignore) applied when hashing Inputs, so touching a file
+	// that doesn't affect the build (logs, node_modules, swap files)
+	// doesn't force a rebuild. Exclude patterns may themselves use "!"
+	// to carve out an exception; Include patterns always win over a
+	// matching Exclude.
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	// Dockerfile is the path to the dockerfile, relative to Context.
+	// Defaults to "Dockerfile".
+	Dockerfile string `yaml:"dockerfile"`
+	// Context is the build context passed to the backend. Defaults to
+	// Inputs[0] for backward compatibility.
+	Context string `yaml:"context"`
+	// BuildArgs are passed through as --build-arg KEY=VALUE.
+	BuildArgs map[string]string `yaml:"build_args"`
+	// Secrets are passed through as --secret.
+	Secrets []string `yaml:"secrets"`
+	// SSH forwards SSH agent sockets or keys, passed through as --ssh.
+	SSH []string `yaml:"ssh"`
+	// Platforms requests one or more target platforms to build for.
+	Platforms []string `yaml:"platforms"`
+	// Target selects a build stage in a multi-stage dockerfile.
+	Target string `yaml:"target"`
+	// CacheFrom and CacheTo import/export build cache, e.g. from a
+	// registry fronting this module's own content-addressable store.
+	CacheFrom []string `yaml:"cache_from"`
+	CacheTo   []string `yaml:"cache_to"`
 }
 
 type BuilderConfig struct {
 	Features map[string]*Feature `yaml:"features"`
+	// HashAlgorithm selects the hash.Hasher used to compute feature
+	// content hashes (sha256, sha1, blake3). Defaults to sha256 when
+	// empty. Overridden by the --hash flag.
+	HashAlgorithm string `yaml:"hash_algorithm"`
+	// Backend selects the builder.Backend used to build images
+	// (docker, buildkit, buildah). Defaults to docker. Overridden by
+	// the --backend flag.
+	Backend string `yaml:"backend"`
 }
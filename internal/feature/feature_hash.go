@@ -6,10 +6,16 @@ import (
 
 	"github.com/maisam9060/platform-api/internal/config"
 	"github.com/maisam9060/platform-api/internal/hash"
+	"github.com/maisam9060/platform-api/internal/ignore"
 )
 
-// ComputeFeatureHash calculates hash of feature
+// ComputeFeatureHash calculates the content hash of a feature using h,
+// prefixed with h.Name() (e.g. "sha256:abcdef..."). Prefixing the
+// algorithm means a cache keyed on this hash automatically misses
+// after hash_algorithm changes, rather than comparing digests from two
+// different algorithms as if they were the same.
 func ComputeFeatureHash(
+	h hash.Hasher,
 	f *config.Feature,
 	depHashes map[string]string,
 ) (string, error) {
@@ -17,26 +23,28 @@ func ComputeFeatureHash(
 	var parts []string
 
 	// Hash command
-	parts = append(parts, hash.HashString(f.Command))
+	parts = append(parts, hash.HashString(h, f.Command))
 
-	// Hash inputs
+	// Hash inputs, skipping whatever f.Include/f.Exclude (and any
+	// .builderignore under the input) rule out.
+	matcher := ignore.FromFeature(f.Exclude, f.Include)
 	for _, input := range f.Inputs {
-		h, err := hash.HashDir(input)
+		d, err := hash.HashDir(h, input, matcher)
 		if err != nil {
 			return "", err
 		}
-		parts = append(parts, h)
+		parts = append(parts, d)
 	}
 
 	// Hash dependencies (sorted for determinism)
 	var deps []string
-	for dep, h := range depHashes {
-		deps = append(deps, dep+h)
+	for dep, d := range depHashes {
+		deps = append(deps, dep+d)
 	}
 	sort.Strings(deps)
 
 	parts = append(parts, deps...)
 
 	final := strings.Join(parts, "|")
-	return hash.HashString(final), nil
+	return h.Name() + ":" + hash.HashString(h, final), nil
 }
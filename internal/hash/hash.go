@@ -1,43 +1,130 @@
 package hash
 
 import (
+	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+
+	"github.com/maisam9060/platform-api/internal/ignore"
+	"lukechampine.com/blake3"
 )
 
-// HashString hashes a string
-func HashString(s string) string {
-	h := sha256.Sum256([]byte(s))
-	return hex.EncodeToString(h[:])
+// Hasher constructs a hash.Hash for one algorithm and names it, so the
+// name can be persisted alongside a computed digest (e.g.
+// "sha256:abcdef...") and checked back against it later.
+type Hasher interface {
+	New() hash.Hash
+	Name() string
+}
+
+type sha256Hasher struct{}
+
+func (sha256Hasher) New() hash.Hash { return sha256.New() }
+func (sha256Hasher) Name() string   { return "sha256" }
+
+type sha1Hasher struct{}
+
+func (sha1Hasher) New() hash.Hash { return sha1.New() }
+func (sha1Hasher) Name() string   { return "sha1" }
+
+type blake3Hasher struct{}
+
+func (blake3Hasher) New() hash.Hash { return blake3.New(32, nil) }
+func (blake3Hasher) Name() string   { return "blake3" }
+
+var registry = map[string]Hasher{
+	"sha256": sha256Hasher{},
+	"sha1":   sha1Hasher{},
+	"blake3": blake3Hasher{},
 }
 
-// HashFile hashes a file's contents
-func HashFile(path string) (string, error) {
+// Lookup returns the registered Hasher for name.
+func Lookup(name string) (Hasher, error) {
+	h, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("hash: unknown algorithm %q", name)
+	}
+	return h, nil
+}
+
+// Default is the Hasher used when builder.yaml and --hash don't
+// specify one.
+func Default() Hasher {
+	return sha256Hasher{}
+}
+
+// HashString hashes a string with h.
+func HashString(h Hasher, s string) string {
+	sum := h.New()
+	sum.Write([]byte(s))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// HashFile hashes a file's contents with h.
+func HashFile(h Hasher, path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
+	sum := h.New()
+	if _, err := io.Copy(sum, f); err != nil {
 		return "", err
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return hex.EncodeToString(sum.Sum(nil)), nil
 }
 
-// HashDir recursively hashes all files in a directory
-func HashDir(dir string) (string, error) {
+// HashDir recursively hashes all files in a directory with h, skipping
+// anything matcher excludes. matcher may be nil. HashDir also loads a
+// .builderignore file at dir's root, if present, and applies it after
+// matcher so it can override the caller's patterns for this directory.
+// If dir is actually a file, HashDir hashes that file directly.
+func HashDir(h Hasher, dir string, matcher *ignore.Matcher) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return HashFile(h, dir)
+	}
+
+	local, err := ignore.ReadIgnoreFile(filepath.Join(dir, ignore.BuilderIgnoreFile))
+	if err != nil {
+		return "", err
+	}
+	effective := ignore.NewMatcher(append(matcher.Patterns(), local...))
+
 	var files []string
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if path == dir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		segments := strings.Split(rel, string(filepath.Separator))
+
+		if effective.Match(segments, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if !info.IsDir() {
 			files = append(files, path)
 		}
@@ -49,14 +136,14 @@ func HashDir(dir string) (string, error) {
 
 	sort.Strings(files)
 
-	h := sha256.New()
+	sum := h.New()
 	for _, f := range files {
-		fileHash, err := HashFile(f)
+		fileHash, err := HashFile(h, f)
 		if err != nil {
 			return "", err
 		}
-		h.Write([]byte(fileHash))
+		sum.Write([]byte(fileHash))
 	}
 
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return hex.EncodeToString(sum.Sum(nil)), nil
 }
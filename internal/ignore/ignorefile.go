@@ -0,0 +1,57 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// BuilderIgnoreFile is the name of the ignore file HashDir auto-loads
+// from the root of each directory it hashes, if present.
+const BuilderIgnoreFile = ".builderignore"
+
+// FromFeature builds the Matcher for a feature's Include/Exclude
+// globs: Exclude patterns are parsed as ordinary gitignore rules (one
+// can itself start with "!" to carve out an exception), and Include
+// patterns always act as an override regardless of a leading "!",
+// since their purpose is to re-include whatever an Exclude pattern
+// matched.
+func FromFeature(exclude, include []string) *Matcher {
+	var patterns []*Pattern
+	for _, raw := range exclude {
+		patterns = append(patterns, ParsePattern(raw, nil))
+	}
+	for _, raw := range include {
+		pat := ParsePattern(strings.TrimPrefix(raw, "!"), nil)
+		pat.negate = true
+		patterns = append(patterns, pat)
+	}
+	return NewMatcher(patterns)
+}
+
+// ReadIgnoreFile reads gitignore-style patterns from path, returning
+// nil patterns (not an error) if the file doesn't exist.
+func ReadIgnoreFile(path string) ([]*Pattern, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []*Pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, ParsePattern(line, nil))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
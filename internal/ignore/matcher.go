@@ -0,0 +1,35 @@
+package ignore
+
+// Matcher evaluates an ordered list of patterns against a path. Later
+// patterns override earlier ones, mirroring gitignore's "last match
+// wins" semantics.
+type Matcher struct {
+	patterns []*Pattern
+}
+
+// NewMatcher returns a Matcher evaluating patterns in order.
+func NewMatcher(patterns []*Pattern) *Matcher {
+	return &Matcher{patterns: patterns}
+}
+
+// Patterns returns m's patterns, or nil if m is nil.
+func (m *Matcher) Patterns() []*Pattern {
+	if m == nil {
+		return nil
+	}
+	return m.patterns
+}
+
+// Match reports whether path should be excluded.
+func (m *Matcher) Match(path []string, isDir bool) bool {
+	excluded := false
+	for _, p := range m.Patterns() {
+		switch p.Match(path, isDir) {
+		case Exclude:
+			excluded = true
+		case Include:
+			excluded = false
+		}
+	}
+	return excluded
+}
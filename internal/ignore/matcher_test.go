@@ -0,0 +1,90 @@
+package ignore
+
+import "testing"
+
+func TestMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     []string
+		isDir    bool
+		want     bool
+	}{
+		{
+			name:     "unanchored matches at any depth",
+			patterns: []string{"build"},
+			path:     []string{"sub", "build"},
+			want:     true,
+		},
+		{
+			name:     "anchored leading slash matches only at root",
+			patterns: []string{"/build"},
+			path:     []string{"sub", "build"},
+			want:     false,
+		},
+		{
+			name:     "anchored leading slash matches the root entry",
+			patterns: []string{"/build"},
+			path:     []string{"build"},
+			want:     true,
+		},
+		{
+			name:     "interior slash is anchored too",
+			patterns: []string{"vendor/modules.txt"},
+			path:     []string{"sub", "vendor", "modules.txt"},
+			want:     false,
+		},
+		{
+			name:     "double star matches any number of segments",
+			patterns: []string{"**/*.go"},
+			path:     []string{"a", "b", "c.go"},
+			want:     true,
+		},
+		{
+			name:     "directory-only pattern ignores files",
+			patterns: []string{"node_modules/"},
+			path:     []string{"node_modules"},
+			isDir:    false,
+			want:     false,
+		},
+		{
+			name:     "directory-only pattern matches directories",
+			patterns: []string{"node_modules/"},
+			path:     []string{"node_modules"},
+			isDir:    true,
+			want:     true,
+		},
+		{
+			name:     "later negation overrides an earlier exclude",
+			patterns: []string{"*.log", "!keep.log"},
+			path:     []string{"keep.log"},
+			want:     false,
+		},
+		{
+			name:     "last match wins",
+			patterns: []string{"!*.log", "*.log"},
+			path:     []string{"build.log"},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patterns []*Pattern
+			for _, raw := range tt.patterns {
+				patterns = append(patterns, ParsePattern(raw, nil))
+			}
+			m := NewMatcher(patterns)
+			if got := m.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%v, isDir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilMatcherMatchesNothing(t *testing.T) {
+	var m *Matcher
+	if m.Match([]string{"anything"}, false) {
+		t.Error("nil Matcher should never exclude a path")
+	}
+}
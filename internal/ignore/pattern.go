@@ -0,0 +1,122 @@
+// Package ignore implements gitignore-style path matching, modeled on
+// go-git's plumbing/format/gitignore, so callers can exclude (and
+// selectively re-include) paths using familiar glob syntax.
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// MatchResult is the outcome of testing a path against a Pattern.
+type MatchResult int
+
+const (
+	NoMatch MatchResult = iota
+	Exclude
+	Include
+)
+
+// Pattern is a single gitignore-style rule: the domain it's rooted
+// at, its glob segments, whether it's anchored to that domain root
+// (a leading or interior "/") rather than matching at any depth,
+// whether it only matches directories (trailing "/"), and whether it
+// negates an earlier exclude (leading "!").
+type Pattern struct {
+	domain   []string
+	segments []string
+	anchored bool
+	dirOnly  bool
+	negate   bool
+}
+
+// ParsePattern parses a single gitignore-style line, rooted at
+// domain (nil for patterns that apply from the matcher's own root).
+func ParsePattern(raw string, domain []string) *Pattern {
+	p := raw
+
+	negate := strings.HasPrefix(p, "!")
+	if negate {
+		p = p[1:]
+	}
+
+	dirOnly := strings.HasSuffix(p, "/")
+	if dirOnly {
+		p = strings.TrimSuffix(p, "/")
+	}
+
+	// A leading or interior "/" roots the pattern at domain, matching
+	// go-git's isAbsolute: anything else matches at any depth.
+	anchored := strings.ContainsRune(p, '/')
+
+	p = strings.TrimPrefix(p, "/")
+
+	return &Pattern{
+		domain:   domain,
+		segments: strings.Split(p, "/"),
+		anchored: anchored,
+		dirOnly:  dirOnly,
+		negate:   negate,
+	}
+}
+
+// Match tests path (its segments relative to the matcher's root)
+// against the pattern, reporting Exclude/Include if it applies and
+// NoMatch otherwise.
+func (p *Pattern) Match(path []string, isDir bool) MatchResult {
+	if len(path) <= len(p.domain) {
+		return NoMatch
+	}
+	for i, e := range p.domain {
+		if path[i] != e {
+			return NoMatch
+		}
+	}
+	rel := path[len(p.domain):]
+
+	var matched bool
+	if p.anchored {
+		// Anchored: the pattern spells out a path from the domain
+		// root, "**" standing in for any number of segments.
+		matched = matchSegments(p.segments, rel)
+	} else {
+		// Unanchored: matches this entry's own name at any depth.
+		matched, _ = filepath.Match(p.segments[0], rel[len(rel)-1])
+	}
+
+	if !matched {
+		return NoMatch
+	}
+	if p.dirOnly && !isDir {
+		return NoMatch
+	}
+	if p.negate {
+		return Include
+	}
+	return Exclude
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return true
+		}
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}